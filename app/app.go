@@ -1,11 +1,29 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"github.com/caasmo/restinpieces/cache"
 	dbIface "github.com/caasmo/restinpieces/db"
 	"github.com/caasmo/restinpieces/router"
+	"log"
 )
 
+// Build information, set at link time:
+//
+//	go build -ldflags "-X github.com/caasmo/restinpieces/app.Version=v0.1.0 \
+//	    -X github.com/caasmo/restinpieces/app.Commit=$(git rev-parse --short HEAD) \
+//	    -X github.com/caasmo/restinpieces/app.BuildTime=$(date -u +%FT%TZ)"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// BuildInfoPath is the route of the BuildInfo handler. Like the build
+// information it can be set at link time with -X.
+var BuildInfoPath = "/version"
+
 // App is the application wide context.
 // db connections and permanent structs should go here.
 //
@@ -15,6 +33,9 @@ type App struct {
 	db          *dbIface.Db
 	routerParam router.ParamGeter
 	cache       cache.Cache
+
+	startHooks    []func(context.Context) error
+	shutdownHooks []func(context.Context) error
 }
 
 // just 1 method
@@ -25,7 +46,42 @@ func New(d *dbIface.Db, p router.ParamGeter, c cache.Cache) *App {
 	return &App{db: d, routerParam: p, cache: c}
 }
 
-// Close all
-func (a *App) Close() {
-	a.db.Close()
+// Close all, waiting at most until ctx is done for resources in use.
+func (a *App) Close(ctx context.Context) error {
+	return a.db.Close(ctx)
+}
+
+// OnStart registers fn to run once the app is fully built, before the server
+// accepts requests. Hooks run in registration order.
+func (a *App) OnStart(fn func(ctx context.Context) error) {
+	a.startHooks = append(a.startHooks, fn)
+}
+
+// Start runs the registered start hooks, stopping at the first error. The
+// server must not be started if Start fails.
+func (a *App) Start(ctx context.Context) error {
+	for i, fn := range a.startHooks {
+		if err := fn(ctx); err != nil {
+			return fmt.Errorf("start hook %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// OnShutdown registers fn to run on graceful shutdown, after the server has
+// stopped accepting requests. Hooks run in registration order and share the
+// server shutdown timeout through ctx.
+func (a *App) OnShutdown(fn func(ctx context.Context) error) {
+	a.shutdownHooks = append(a.shutdownHooks, fn)
+}
+
+// Shutdown runs the registered shutdown hooks. Errors are logged, a failing
+// hook does not prevent the next ones from running.
+func (a *App) Shutdown(ctx context.Context) {
+	for _, fn := range a.shutdownHooks {
+		if err := fn(ctx); err != nil {
+			log.Printf("shutdown hook error: %v\n", err)
+		}
+	}
 }
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/caasmo/restinpieces/router/httprouter"
+	"github.com/caasmo/restinpieces/server"
+	"testing"
+)
+
+func TestApp_ShutdownHooksRunOnRunContextCancel(t *testing.T) {
+	a := New(nil, nil, nil)
+
+	var ran []string
+	var hookCtxs []context.Context
+	hook := func(name string, err error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			ran = append(ran, name)
+			hookCtxs = append(hookCtxs, ctx)
+			return err
+		}
+	}
+	a.OnShutdown(hook("first", nil))
+	a.OnShutdown(hook("failing", errors.New("flush failed")))
+	a.OnShutdown(hook("last", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := server.RunContext(ctx, "127.0.0.1:0", httprouter.New(), nil, a.Shutdown); err != nil {
+		t.Fatalf("RunContext() = %v, want nil", err)
+	}
+
+	want := []string{"first", "failing", "last"}
+	if len(ran) != len(want) {
+		t.Fatalf("hooks ran %v, want %v", ran, want)
+	}
+	for i := range want {
+		if ran[i] != want[i] {
+			t.Errorf("hook %d = %q, want %q", i, ran[i], want[i])
+		}
+		if _, ok := hookCtxs[i].Deadline(); !ok {
+			t.Errorf("hook %q context has no deadline, want the shutdown timeout", ran[i])
+		}
+	}
+}
+
+func TestApp_StartStopsAtFailingHook(t *testing.T) {
+	a := New(nil, nil, nil)
+
+	errHook := errors.New("cache not warm")
+	var ran []string
+	a.OnStart(func(ctx context.Context) error {
+		ran = append(ran, "first")
+		return nil
+	})
+	a.OnStart(func(ctx context.Context) error {
+		ran = append(ran, "failing")
+		return errHook
+	})
+	a.OnStart(func(ctx context.Context) error {
+		ran = append(ran, "never")
+		return nil
+	})
+
+	err := a.Start(context.Background())
+	if !errors.Is(err, errHook) {
+		t.Fatalf("Start() = %v, want wrapping %v", err, errHook)
+	}
+	if len(ran) != 2 || ran[1] != "failing" {
+		t.Errorf("hooks ran %v, want [first failing]", ran)
+	}
+}
+
+func TestApp_StartWithoutHooks(t *testing.T) {
+	if err := New(nil, nil, nil).Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v, want nil", err)
+	}
+}
@@ -3,9 +3,11 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"math/rand"
 	"net/http"
 	"os"
+	"runtime"
 	"strconv"
 	"time"
 )
@@ -14,19 +16,45 @@ import (
 //
 // Differentiate from the Handler by using suffix
 
+// PrettyJSON lets the pretty query parameter, like /version?pretty, indent
+// JSON responses for reading in a terminal. Off by default, turn it on only
+// outside production.
+var PrettyJSON = false
+
+// writeJSON encodes v as the response body, compact unless PrettyJSON is on
+// and the request has the pretty query parameter.
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	var b []byte
+	var err error
+	if _, pretty := r.URL.Query()["pretty"]; PrettyJSON && pretty {
+		b, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		b, err = json.Marshal(v)
+	}
+
+	if err != nil {
+		log.Printf("json encode error: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(b, '\n'))
+}
+
 func (a *App) Admin(w http.ResponseWriter, r *http.Request) {
 
 	user := "testuser"
 	//user := context.Get(r, "user")
 	// Maybe other operations on the database
-	json.NewEncoder(w).Encode(user)
+	writeJSON(w, r, user)
 }
 
 func (a *App) Tea(w http.ResponseWriter, r *http.Request) {
 	//params := context.Get(r, "params").(httprouter.Params)
 	//log.Println(params.ByName("id"))
 	// tea := getTea(a.db, params.ByName("id"))
-	json.NewEncoder(w).Encode(nil)
+	writeJSON(w, r, nil)
 }
 
 func (a *App) ExampleSqliteReadRandom(w http.ResponseWriter, r *http.Request) {
@@ -153,6 +181,18 @@ func (a *App) BenchmarkSqliteRWRatioPool(w http.ResponseWriter, r *http.Request)
 	w.Write([]byte(`{"random num":` + strconv.Itoa(nint) + `,"sum":` + strconv.Itoa(sum) + `,"operation":"` + op + `"}`))
 }
 
+// BuildInfo serves the version of the running binary, see Version.
+func (a *App) BuildInfo(w http.ResponseWriter, r *http.Request) {
+	info := struct {
+		Version   string `json:"version"`
+		Commit    string `json:"commit"`
+		BuildTime string `json:"build_time"`
+		GoVersion string `json:"go_version"`
+	}{Version, Commit, BuildTime, runtime.Version()}
+
+	writeJSON(w, r, info)
+}
+
 func (a *App) Index(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Welcome!")
 }
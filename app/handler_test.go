@@ -0,0 +1,76 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestBuildInfo(t *testing.T) {
+	defer func(v, c, b string) { Version, Commit, BuildTime = v, c, b }(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "v1.2.3", "abc1234", "2026-10-15T10:00:00Z"
+
+	w := httptest.NewRecorder()
+	New(nil, nil, nil).BuildInfo(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var got map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"version":    "v1.2.3",
+		"commit":     "abc1234",
+		"build_time": "2026-10-15T10:00:00Z",
+		"go_version": runtime.Version(),
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestWriteJSON_Pretty(t *testing.T) {
+	defer func(p bool) { PrettyJSON = p }(PrettyJSON)
+
+	v := map[string]string{"version": "dev"}
+	compact := "{\"version\":\"dev\"}\n"
+	indented := "{\n  \"version\": \"dev\"\n}\n"
+
+	tests := []struct {
+		prettyJSON bool
+		url        string
+		want       string
+	}{
+		{false, "/version", compact},
+		{false, "/version?pretty", compact},
+		{true, "/version", compact},
+		{true, "/version?pretty", indented},
+	}
+
+	for _, tt := range tests {
+		PrettyJSON = tt.prettyJSON
+		w := httptest.NewRecorder()
+		writeJSON(w, httptest.NewRequest(http.MethodGet, tt.url, nil), v)
+
+		if got := w.Body.String(); got != tt.want {
+			t.Errorf("PrettyJSON=%v %s: body = %q, want %q", tt.prettyJSON, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestWriteJSON_EncodeError(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeJSON(w, httptest.NewRequest(http.MethodGet, "/", nil), func() {})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
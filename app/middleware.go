@@ -1,8 +1,16 @@
 package app
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,7 +23,7 @@ func (a *App) Auth(next http.Handler) http.Handler {
 		//user, err := map[string]interface{}{}, errors.New("test")
 		//user := authToken
 		// user, err := getUser(c.db, authToken)
-		log.Println(authToken)
+		log.Printf("[%s] %q authorization header: %t\n", r.Method, redactURL(r.URL), authToken != "")
 
 		// if return in middleware, no next, chain stopped
 		//if err != nil {
@@ -36,8 +44,278 @@ func (a *App) Logger(next http.Handler) http.Handler {
 		t1 := time.Now()
 		next.ServeHTTP(w, r)
 		t2 := time.Now()
-		log.Printf("[%s] %q %v\n", r.Method, r.URL.String(), t2.Sub(t1))
+		log.Printf("[%s] %q %v\n", r.Method, redactURL(r.URL), t2.Sub(t1))
 	}
 
 	return http.HandlerFunc(fn)
 }
+
+// RedactedQueryParams holds the query parameter names, case insensitive,
+// whose values are masked in request logs, in the request URL and in the
+// Referer. Request logs never write credential headers like Authorization or
+// Cookie, only Referer and User-Agent in the combined format.
+var RedactedQueryParams = []string{"token", "access_token", "refresh_token", "api_key", "apikey", "password", "secret"}
+
+const redacted = "REDACTED"
+
+func isRedacted(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactReferer returns the Referer with its query redacted by redactURL.
+func redactReferer(referer string) string {
+	if referer == "" {
+		return referer
+	}
+
+	u, err := url.Parse(referer)
+	if err != nil {
+		return redacted
+	}
+	return redactURL(u)
+}
+
+// redactURL returns u as string with the values of RedactedQueryParams
+// masked. Parameter order and encoding are kept as sent.
+func redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+
+	params := strings.Split(u.RawQuery, "&")
+	for i, p := range params {
+		rawKey := p
+		if j := strings.IndexByte(p, '='); j >= 0 {
+			rawKey = p[:j]
+		}
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if isRedacted(RedactedQueryParams, key) {
+			params[i] = rawKey + "=" + redacted
+		}
+	}
+
+	ru := *u
+	ru.RawQuery = strings.Join(params, "&")
+	return ru.String()
+}
+
+// accessLog writes access log lines as they are, without the log package
+// date prefix, so log processors can parse them.
+var accessLog = log.New(os.Stdout, "", 0)
+
+// CommonLogger logs requests in the Apache Common Log Format, an alternative
+// to Logger for existing log tooling:
+//
+//	127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /teas/1 HTTP/1.1" 200 2326
+func (a *App) CommonLogger(next http.Handler) http.Handler {
+	return accessLogger(next, false)
+}
+
+// CombinedLogger logs requests in the Apache Combined Log Format, the Common
+// Log Format followed by the quoted Referer and User-Agent headers.
+func (a *App) CombinedLogger(next http.Handler) http.Handler {
+	return accessLogger(next, true)
+}
+
+func accessLogger(next http.Handler, combined bool) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		t := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		accessLog.Print(accessLogLine(r, t, sw.status, sw.size, combined))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+func accessLogLine(r *http.Request, t time.Time, status, size int, combined bool) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if host == "" {
+		host = "-"
+	}
+
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	bytes := "-"
+	if size > 0 {
+		bytes = strconv.Itoa(size)
+	}
+
+	line := host + " - " + user + " [" + t.Format("02/Jan/2006:15:04:05 -0700") + "] " +
+		`"` + clfEscape(r.Method+" "+redactURL(r.URL)+" "+r.Proto) + `" ` +
+		strconv.Itoa(status) + " " + bytes
+
+	if combined {
+		line += ` "` + clfField(redactReferer(r.Referer())) + `" "` + clfField(r.UserAgent()) + `"`
+	}
+
+	return line
+}
+
+// clfField escapes a quoted field, empty values are logged as -.
+func clfField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return clfEscape(s)
+}
+
+var clfEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+
+// statusWriter records the status code and body size written by a handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// HandlerTimeout is the default time a handler has to complete before the
+// client gets a 503. It must stay below server.WriteTimeout, otherwise the
+// connection is closed before the 503 can be written.
+const HandlerTimeout = 2 * time.Second
+
+const timeoutBody = `{"error":"handler timeout"}`
+
+// Timeout answers 503 with a json error when next does not complete in
+// HandlerTimeout. See TimeoutAfter for per route durations.
+func (a *App) Timeout(next http.Handler) http.Handler {
+	return a.TimeoutAfter(HandlerTimeout)(next)
+}
+
+// TimeoutAfter returns a Timeout middleware with duration d, for routes that
+// need more or less time than HandlerTimeout:
+//
+//	r.Get("/slow", commonMiddleware.Append(ap.TimeoutAfter(10*time.Second)).ThenFunc(ap.Slow))
+//
+// As with http.TimeoutHandler the response of next is buffered and the
+// request context is canceled on timeout.
+func (a *App) TimeoutAfter(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		th := http.TimeoutHandler(next, d, timeoutBody)
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			th.ServeHTTP(jsonTimeoutWriter{w}, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// jsonTimeoutWriter sets the json content type of the timeout body.
+// http.TimeoutHandler copies the handler headers before writing the status,
+// so a 503 without content type can only be the timeout response or a
+// handler 503 that did not set one.
+type jsonTimeoutWriter struct {
+	http.ResponseWriter
+}
+
+func (w jsonTimeoutWriter) WriteHeader(code int) {
+	if code == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+const (
+	CSRFCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// CSRFSafeMethods are not checked by CSRF, routes must not change state on
+// them. Any other method is protected.
+var CSRFSafeMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// CSRFSecureCookie sets the Secure attribute of the CSRFCookie. It is on by
+// default, also behind a tls terminating proxy, turn it off only to develop
+// over plain http.
+var CSRFSecureCookie = true
+
+// CSRF protects cookie authenticated routes with the double submit cookie
+// pattern. Requests with safe methods are passed and receive a random token
+// in the CSRFCookie if they lack one. Any other method must send the cookie
+// value back in the CSRFHeader, which a cross site page can not read, or is
+// rejected with 403.
+func (a *App) CSRF(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(CSRFCookie)
+
+		if CSRFSafeMethods[r.Method] {
+			if err != nil {
+				token, err := newCSRFToken()
+				if err != nil {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+
+				// not HttpOnly, the client script reads it to set the header
+				http.SetCookie(w, &http.Cookie{
+					Name:     CSRFCookie,
+					Value:    token,
+					Path:     "/",
+					Secure:   CSRFSecureCookie,
+					SameSite: http.SameSiteStrictMode,
+				})
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := r.Header.Get(CSRFHeader)
+		if err != nil || header == "" || subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) != 1 {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
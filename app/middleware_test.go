@@ -0,0 +1,252 @@
+package app
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		sleep time.Duration
+		code  int
+		ct    string
+		body  string
+	}{
+		{"fast", 0, http.StatusOK, "text/plain", "done"},
+		{"slow", 200 * time.Millisecond, http.StatusServiceUnavailable, "application/json", timeoutBody},
+	}
+
+	for _, tt := range tests {
+		h := New(nil, nil, nil).TimeoutAfter(50 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(tt.sleep):
+			case <-r.Context().Done():
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("done"))
+		}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if w.Code != tt.code {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.ct {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.name, ct, tt.ct)
+		}
+		if body := w.Body.String(); body != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.name, body, tt.body)
+		}
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	h := New(nil, nil, nil).CSRF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	// a safe method passes and gets the token cookie
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET status = %d, want %d", w.Code, http.StatusOK)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != CSRFCookie || cookies[0].Value == "" {
+		t.Fatalf("GET cookies = %v, want a %s cookie", cookies, CSRFCookie)
+	}
+	token := cookies[0]
+	if !token.Secure {
+		t.Error("cookie not Secure")
+	}
+
+	tests := []struct {
+		name   string
+		cookie bool
+		header string
+		code   int
+	}{
+		{"valid token", true, token.Value, http.StatusOK},
+		{"missing header", true, "", http.StatusForbidden},
+		{"wrong header", true, "x" + token.Value, http.StatusForbidden},
+		{"missing cookie", false, token.Value, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		if tt.cookie {
+			r.AddCookie(token)
+		}
+		if tt.header != "" {
+			r.Header.Set(CSRFHeader, tt.header)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.code)
+		}
+	}
+}
+
+func TestCSRF_SafeMethodsSkipCheck(t *testing.T) {
+	h := New(nil, nil, nil).CSRF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for m := range CSRFSafeMethods {
+		r := httptest.NewRequest(m, "/", nil)
+		r.AddCookie(&http.Cookie{Name: CSRFCookie, Value: "token"})
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s without header: status = %d, want %d", m, w.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestAccessLogLine(t *testing.T) {
+	ts := time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60))
+
+	tests := []struct {
+		name     string
+		req      func() *http.Request
+		status   int
+		size     int
+		combined bool
+		want     string
+	}{
+		{
+			name: "common",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/teas/1", nil)
+				r.RemoteAddr = "127.0.0.1:54321"
+				return r
+			},
+			status: http.StatusOK,
+			size:   2326,
+			want:   `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /teas/1 HTTP/1.1" 200 2326`,
+		},
+		{
+			name: "common basic auth user, no body, implicit status",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/admin?a=1", nil)
+				r.RemoteAddr = "[::1]:54321"
+				r.SetBasicAuth("frank", "secret")
+				return r
+			},
+			want: `::1 - frank [10/Oct/2000:13:55:36 -0700] "POST /admin?a=1 HTTP/1.1" 200 -`,
+		},
+		{
+			name: "empty remote addr",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = ""
+				return r
+			},
+			status: http.StatusNoContent,
+			want:   `- - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 204 -`,
+		},
+		{
+			name: "combined",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = "127.0.0.1:54321"
+				r.Header.Set("Referer", "http://example.com/start")
+				r.Header.Set("User-Agent", `Mozilla/4.08 "quoted"`)
+				return r
+			},
+			status:   http.StatusOK,
+			size:     8,
+			combined: true,
+			want:     `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 200 8 "http://example.com/start" "Mozilla/4.08 \"quoted\""`,
+		},
+		{
+			name: "combined without referer and user agent",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = "127.0.0.1:54321"
+				return r
+			},
+			status:   http.StatusNotFound,
+			size:     19,
+			combined: true,
+			want:     `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 404 19 "-" "-"`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := accessLogLine(tt.req(), ts, tt.status, tt.size, tt.combined); got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuth_DoesNotLogAuthorization(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	h := New(nil, nil, nil).Auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r := httptest.NewRequest(http.MethodGet, "/admin?token=querysecret", nil)
+	r.Header.Set("Authorization", "Bearer headersecret")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	got := buf.String()
+	if strings.Contains(got, "headersecret") || strings.Contains(got, "querysecret") {
+		t.Errorf("log contains a secret: %s", got)
+	}
+	if !strings.Contains(got, "authorization header: true") {
+		t.Errorf("log = %q, want authorization header presence", got)
+	}
+}
+
+func TestCombinedLogger_Redacted(t *testing.T) {
+	var buf bytes.Buffer
+	accessLog.SetOutput(&buf)
+	defer accessLog.SetOutput(os.Stdout)
+
+	h := New(nil, nil, nil).CombinedLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r := httptest.NewRequest(http.MethodGet, "/teas/1?api_key=querysecret&page=2", nil)
+	r.Header.Set("Authorization", "Bearer headersecret")
+	r.Header.Set("Cookie", "session=cookiesecret")
+	r.Header.Set("Referer", "http://h/p?token=referersecret")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	got := buf.String()
+	for _, secret := range []string{"querysecret", "headersecret", "cookiesecret", "referersecret"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("access log contains %s: %s", secret, got)
+		}
+	}
+	for _, want := range []string{"/teas/1?api_key=" + redacted + "&page=2", `"http://h/p?token=` + redacted + `"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("access log = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestRedactReferer(t *testing.T) {
+	tests := []struct {
+		referer, want string
+	}{
+		{"", ""},
+		{"http://h/p", "http://h/p"},
+		{"http://h/p?token=secret&page=2", "http://h/p?token=" + redacted + "&page=2"},
+		{"http://h/%zz?token=secret", redacted},
+	}
+
+	for _, tt := range tests {
+		if got := redactReferer(tt.referer); got != tt.want {
+			t.Errorf("redactReferer(%q) = %q, want %q", tt.referer, got, tt.want)
+		}
+	}
+}
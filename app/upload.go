@@ -0,0 +1,85 @@
+package app
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// Upload limits in bytes. UploadMaxPartSize caps each part, file or form
+// field, UploadMaxTotalSize all parts of a request together.
+var (
+	UploadMaxPartSize  int64 = 32 << 20
+	UploadMaxTotalSize int64 = 64 << 20
+)
+
+var (
+	ErrUploadPartTooLarge  = errors.New("upload: part too large")
+	ErrUploadTotalTooLarge = errors.New("upload: request too large")
+)
+
+// Upload streams the multipart/form-data body of r to fn part by part,
+// nothing is buffered. fn reads the part content from body, a read past
+// UploadMaxPartSize or UploadMaxTotalSize fails with ErrUploadPartTooLarge
+// or ErrUploadTotalTooLarge. The content fn does not read is still read and
+// counted, so Upload returns those errors even for skipped parts.
+//
+// Upload returns the first error of fn, the limits or the body parsing.
+func Upload(r *http.Request, fn func(part *multipart.Part, body io.Reader) error) error {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		body := &uploadLimitReader{r: part, total: &total}
+		err = fn(part, body)
+		if err == nil {
+			_, err = io.Copy(io.Discard, body)
+		}
+		part.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// uploadLimitReader counts the bytes read of a part and of the request.
+type uploadLimitReader struct {
+	r     io.Reader
+	part  int64
+	total *int64
+}
+
+func (l *uploadLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.part += int64(n)
+	*l.total += int64(n)
+
+	// return only the bytes within the limits
+	if over := l.part - UploadMaxPartSize; over > 0 {
+		return n - int(min64(over, int64(n))), ErrUploadPartTooLarge
+	}
+	if over := *l.total - UploadMaxTotalSize; over > 0 {
+		return n - int(min64(over, int64(n))), ErrUploadTotalTooLarge
+	}
+
+	return n, err
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
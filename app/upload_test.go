@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newUploadRequest returns a multipart/form-data request with a file part
+// per content.
+func newUploadRequest(t *testing.T, contents ...string) *http.Request {
+	t.Helper()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for i, c := range contents {
+		fw, err := mw.CreateFormFile("file", "f"+string(rune('a'+i))+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(c))
+	}
+	mw.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &buf)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func setUploadLimits(t *testing.T, part, total int64) {
+	t.Helper()
+
+	p, tot := UploadMaxPartSize, UploadMaxTotalSize
+	t.Cleanup(func() { UploadMaxPartSize, UploadMaxTotalSize = p, tot })
+	UploadMaxPartSize, UploadMaxTotalSize = part, total
+}
+
+func TestUpload_WithinLimits(t *testing.T) {
+	setUploadLimits(t, 10, 20)
+
+	var got []string
+	err := Upload(newUploadRequest(t, "0123456789", "abc"), func(part *multipart.Part, body io.Reader) error {
+		b, err := io.ReadAll(body)
+		got = append(got, part.FileName()+":"+string(b))
+		return err
+	})
+
+	if err != nil {
+		t.Fatalf("Upload() = %v, want nil", err)
+	}
+	if want := "fa.txt:0123456789 fb.txt:abc"; strings.Join(got, " ") != want {
+		t.Errorf("parts = %q, want %q", got, want)
+	}
+}
+
+func TestUpload_OverLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents []string
+		read     bool
+		want     error
+	}{
+		{"part too large", []string{"01234567890"}, true, ErrUploadPartTooLarge},
+		{"total too large", []string{"0123456789", "0123456789", "0"}, true, ErrUploadTotalTooLarge},
+		{"skipped part too large", []string{"01234567890"}, false, ErrUploadPartTooLarge},
+	}
+
+	for _, tt := range tests {
+		setUploadLimits(t, 10, 20)
+
+		err := Upload(newUploadRequest(t, tt.contents...), func(part *multipart.Part, body io.Reader) error {
+			if !tt.read {
+				return nil
+			}
+			b, err := io.ReadAll(body)
+			if int64(len(b)) > UploadMaxPartSize {
+				t.Errorf("%s: read %d bytes, over the part limit", tt.name, len(b))
+			}
+			return err
+		})
+
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: Upload() = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestUpload_NotMultipart(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("{}"))
+	r.Header.Set("Content-Type", "application/json")
+
+	if err := Upload(r, func(*multipart.Part, io.Reader) error { return nil }); err == nil {
+		t.Error("Upload() = nil, want error for a non multipart body")
+	}
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"github.com/caasmo/restinpieces/app"
 	cacheRistretto "github.com/caasmo/restinpieces/cache/ristretto"
 	"github.com/caasmo/restinpieces/db"
 	router "github.com/caasmo/restinpieces/router/httprouter"
 	"github.com/caasmo/restinpieces/server"
+	"log"
 	"os"
 )
 
@@ -23,6 +25,7 @@ func initApp() (*app.App, error) {
 	// cache
 	cache, err := cacheRistretto.New()
 	if err != nil {
+		db.Close(context.Background())
 		return nil, err
 	}
 
@@ -31,16 +34,24 @@ func initApp() (*app.App, error) {
 
 func main() {
 
+	log.Printf("restinpieces %s, commit %s, built %s\n", app.Version, app.Commit, app.BuildTime)
+
 	ap, err := initApp()
 	if err != nil {
-		//log
-		os.Exit(1)
+		log.Printf("init error: %v - exit %d\n", err, server.ExitInit)
+		os.Exit(server.ExitInit)
 	}
 
-	defer ap.Close()
-
 	r := router.New()
 	route(r, ap)
 
-	server.Run(":8080", r)
+	// Run exits the process, the app is closed in its shutdown
+	server.Run(":8080", r, ap.Start, func(ctx context.Context) {
+		ap.Shutdown(ctx)
+		// bounded by ctx, handlers still running past the shutdown
+		// timeout may hold db connections
+		if err := ap.Close(ctx); err != nil {
+			log.Printf("close error: %v\n", err)
+		}
+	})
 }
@@ -8,9 +8,10 @@ import (
 )
 
 func route(r router.Router, ap *app.App) {
-	commonMiddleware := alice.New(ap.Logger)
+	commonMiddleware := alice.New(ap.Logger, ap.Timeout)
 	r.Get("/admin", commonMiddleware.Append(ap.Auth).ThenFunc(ap.Admin))
 	r.Get("/", commonMiddleware.ThenFunc(ap.Index))
+	r.Get(app.BuildInfoPath, commonMiddleware.ThenFunc(ap.BuildInfo))
 	r.Get("/example/sqlite/read/randompk", http.HandlerFunc(ap.ExampleSqliteReadRandom))
 	r.Get("/example/sqlite/writeone/:value", http.HandlerFunc(ap.ExampleWriteOne))
 	//router.Get("/example/ristretto/writeread/:value", http.HandlerFunc(ap.ExampleRistrettoWriteRead))
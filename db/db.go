@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"crawshaw.io/sqlite"
 	"crawshaw.io/sqlite/sqlitex"
 	"fmt"
@@ -11,6 +12,7 @@ type Db struct {
 	pool *sqlitex.Pool
 	//rwConn *sqlitex.Conn
 	rwCh chan *sqlite.Conn
+	size int
 }
 
 //
@@ -32,11 +34,38 @@ func New(path string) (*Db, error) {
 		ch <- conn
 	}(conn, ch)
 
-	return &Db{pool: p, rwCh: ch}, nil
+	return &Db{pool: p, rwCh: ch, size: poolSize}, nil
 }
 
-func (db *Db) Close() {
-	db.Close()
+// Close waits for all connections, the write one included, to be released
+// by their users and closes the pool. If ctx is done first, handlers still
+// hold connections and the pool is left open: sqlitex.Pool.Close would block
+// and panic after sqlitex.PoolCloseTimeout.
+func (db *Db) Close(ctx context.Context) error {
+	select {
+	case conn := <-db.rwCh:
+		db.pool.Put(conn)
+	case <-ctx.Done():
+		return fmt.Errorf("write connection in use: %w", ctx.Err())
+	}
+
+	// holding every connection of the pool means nobody else does
+	conns := make([]*sqlite.Conn, 0, db.size)
+	for len(conns) < db.size {
+		conn := db.pool.Get(ctx)
+		if conn == nil {
+			break
+		}
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		db.pool.Put(conn)
+	}
+	if len(conns) < db.size {
+		return fmt.Errorf("%d connections in use: %w", db.size-len(conns), ctx.Err())
+	}
+
+	return db.pool.Close()
 }
 
 func (db *Db) GetById(id int64) int {
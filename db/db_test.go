@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestDb(t *testing.T) *Db {
+	t.Helper()
+
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestDb_Close(t *testing.T) {
+	db := newTestDb(t)
+
+	if err := db.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+}
+
+func TestDb_CloseWriteConnectionInUse(t *testing.T) {
+	db := newTestDb(t)
+
+	// an Insert in flight holds the write connection
+	conn := <-db.rwCh
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := db.Close(ctx); err == nil {
+		t.Fatal("Close() = nil, want error while the write connection is in use")
+	}
+
+	db.rwCh <- conn
+	if err := db.Close(context.Background()); err != nil {
+		t.Fatalf("Close() after release = %v, want nil", err)
+	}
+}
+
+func TestDb_CloseReadConnectionInUse(t *testing.T) {
+	db := newTestDb(t)
+	if db.size < 2 {
+		t.Skip("pool of one connection, it is the write connection")
+	}
+
+	// a GetById in flight holds a pool connection
+	conn := db.pool.Get(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := db.Close(ctx); err == nil {
+		t.Fatal("Close() = nil, want error while a read connection is in use")
+	}
+
+	db.pool.Put(conn)
+}
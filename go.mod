@@ -6,13 +6,13 @@ require (
 	crawshaw.io/sqlite v0.3.2
 	github.com/julienschmidt/httprouter v1.3.0
 	github.com/justinas/alice v1.2.0
+	github.com/outcaste-io/ristretto v0.1.1-0.20220408180327-81c196f2b5ef
 )
 
 require (
 	github.com/cespare/xxhash/v2 v2.1.1 // indirect
 	github.com/dustin/go-humanize v1.0.0 // indirect
 	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b // indirect
-	github.com/outcaste-io/ristretto v0.1.1-0.20220408180327-81c196f2b5ef // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	golang.org/x/sys v0.0.0-20200930185726-fdedc70b468f // indirect
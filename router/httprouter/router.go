@@ -9,19 +9,109 @@ import (
 
 // Implementation of the router interface
 type Router struct {
-	rt *jshttprouter.Router
+	rt            *jshttprouter.Router
+	trailingSlash router.TrailingSlash
+
+	// registered methods, to look up routes of any method
+	methods []string
 }
 
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.trailingSlash == router.TrailingSlashIgnore {
+		// absolute-form request without path, serve it as "/" instead of
+		// letting httprouter redirect
+		if req.URL.Path == "" {
+			req.URL.Path = "/"
+		}
+		if alt, ok := r.slashVariant(req.URL.Path); ok {
+			req.URL.Path = alt
+		}
+	}
+
 	r.rt.ServeHTTP(w, req)
 }
 
+// slashVariant returns path with the trailing slash toggled when no method
+// has a route for path but some method has one for the toggled form. The
+// request then gets the handler, or the 405, of the registered route.
+func (r *Router) slashVariant(path string) (string, bool) {
+	// "/" has no variant
+	if len(path) < 2 {
+		return "", false
+	}
+
+	alt := path + "/"
+	if path[len(path)-1] == '/' {
+		alt = path[:len(path)-1]
+	}
+
+	found := false
+	for _, m := range r.methods {
+		if h, _, _ := r.rt.Lookup(m, path); h != nil {
+			return "", false
+		}
+		if h, _, _ := r.rt.Lookup(m, alt); h != nil {
+			found = true
+		}
+	}
+
+	return alt, found
+}
+
+func (r *Router) handle(method, path string, handler http.Handler) {
+	r.rt.Handler(method, path, handler)
+	for _, m := range r.methods {
+		if m == method {
+			return
+		}
+	}
+	r.methods = append(r.methods, method)
+}
+
 func (r *Router) Get(path string, handler http.Handler) {
-	r.rt.Handler("GET", path, handler)
+	r.handle(http.MethodGet, path, handler)
+}
+
+func (r *Router) Post(path string, handler http.Handler) {
+	r.handle(http.MethodPost, path, handler)
+}
+
+func (r *Router) Put(path string, handler http.Handler) {
+	r.handle(http.MethodPut, path, handler)
+}
+
+func (r *Router) Patch(path string, handler http.Handler) {
+	r.handle(http.MethodPatch, path, handler)
 }
 
+func (r *Router) Delete(path string, handler http.Handler) {
+	r.handle(http.MethodDelete, path, handler)
+}
+
+// New returns a router with the redirect trailing slash policy.
 func New() router.Router {
-	return &Router{rt: jshttprouter.New()}
+	return NewWithTrailingSlash(router.TrailingSlashRedirect)
+}
+
+// NewWithTrailingSlash returns a router with the given trailing slash policy.
+// It panics on an unknown policy.
+func NewWithTrailingSlash(ts router.TrailingSlash) router.Router {
+	switch ts {
+	case router.TrailingSlashStrict, router.TrailingSlashRedirect, router.TrailingSlashIgnore:
+	default:
+		panic("unknown trailing slash policy '" + string(ts) + "'")
+	}
+
+	rt := jshttprouter.New()
+	// 405 with Allow header when the path exists for other methods.
+	// This is the httprouter default, set explicitly as it is part of the
+	// router.Router contract.
+	rt.HandleMethodNotAllowed = true
+	// Ignore is resolved in ServeHTTP before httprouter can redirect.
+	rt.RedirectTrailingSlash = ts == router.TrailingSlashRedirect
+	// Strict serves only exact paths, no case or dot segment fixing either.
+	rt.RedirectFixedPath = ts != router.TrailingSlashStrict
+	return &Router{rt: rt, trailingSlash: ts}
 }
 
 // Implementation of the router/ParamGeter interface
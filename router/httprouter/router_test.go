@@ -0,0 +1,126 @@
+package httprouter
+
+import (
+	"bufio"
+	"github.com/caasmo/restinpieces/router"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	r := New()
+	r.Post("/api/refresh-auth", http.HandlerFunc(okHandler))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/refresh-auth", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := w.Header().Get("Allow"); allow != "OPTIONS, POST" {
+		t.Errorf("Allow = %q, want %q", allow, "OPTIONS, POST")
+	}
+}
+
+func TestRouter_NotFound(t *testing.T) {
+	r := New()
+	r.Post("/api/refresh-auth", http.HandlerFunc(okHandler))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestRouter_TrailingSlash(t *testing.T) {
+	tests := []struct {
+		policy   router.TrailingSlash
+		method   string
+		path     string
+		code     int
+		location string
+	}{
+		{router.TrailingSlashStrict, http.MethodGet, "/admin", http.StatusOK, ""},
+		{router.TrailingSlashStrict, http.MethodGet, "/admin/", http.StatusNotFound, ""},
+		{router.TrailingSlashStrict, http.MethodGet, "/ADMIN", http.StatusNotFound, ""},
+		{router.TrailingSlashStrict, http.MethodPost, "/api/refresh-auth/", http.StatusNotFound, ""},
+
+		{router.TrailingSlashRedirect, http.MethodGet, "/admin", http.StatusOK, ""},
+		{router.TrailingSlashRedirect, http.MethodGet, "/admin/", http.StatusMovedPermanently, "/admin"},
+		{router.TrailingSlashRedirect, http.MethodGet, "/docs", http.StatusMovedPermanently, "/docs/"},
+		{router.TrailingSlashRedirect, http.MethodPost, "/api/refresh-auth/", http.StatusTemporaryRedirect, "/api/refresh-auth"},
+
+		{router.TrailingSlashIgnore, http.MethodGet, "/admin", http.StatusOK, ""},
+		{router.TrailingSlashIgnore, http.MethodGet, "/admin/", http.StatusOK, ""},
+		{router.TrailingSlashIgnore, http.MethodGet, "/docs", http.StatusOK, ""},
+		{router.TrailingSlashIgnore, http.MethodPost, "/api/refresh-auth/", http.StatusOK, ""},
+		{router.TrailingSlashIgnore, http.MethodPost, "/admin/", http.StatusMethodNotAllowed, ""},
+		{router.TrailingSlashIgnore, http.MethodGet, "/unknown/", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		r := NewWithTrailingSlash(tt.policy)
+		r.Get("/admin", http.HandlerFunc(okHandler))
+		r.Get("/docs/", http.HandlerFunc(okHandler))
+		r.Post("/api/refresh-auth", http.HandlerFunc(okHandler))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if w.Code != tt.code {
+			t.Errorf("%s %s %s: status = %d, want %d", tt.policy, tt.method, tt.path, w.Code, tt.code)
+		}
+		if loc := w.Header().Get("Location"); loc != tt.location {
+			t.Errorf("%s %s %s: Location = %q, want %q", tt.policy, tt.method, tt.path, loc, tt.location)
+		}
+	}
+}
+
+// An absolute-form request line without path reaches the router with an
+// empty URL path.
+func TestRouter_TrailingSlashIgnoreEmptyPath(t *testing.T) {
+	req, err := http.ReadRequest(bufio.NewReader(strings.NewReader("GET http://example.com HTTP/1.1\r\nHost: example.com\r\n\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "" {
+		t.Fatalf("URL.Path = %q, want empty", req.URL.Path)
+	}
+
+	r := NewWithTrailingSlash(router.TrailingSlashIgnore)
+	r.Get("/", http.HandlerFunc(okHandler))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if loc := w.Header().Get("Location"); loc != "" {
+		t.Errorf("Location = %q, want no redirect", loc)
+	}
+	if w.Body.String() != "ok" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "ok")
+	}
+}
+
+func TestNewWithTrailingSlash_UnknownPolicy(t *testing.T) {
+	for _, ts := range []router.TrailingSlash{"", "redirekt"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewWithTrailingSlash(%q) did not panic", ts)
+				}
+			}()
+			NewWithTrailingSlash(ts)
+		}()
+	}
+}
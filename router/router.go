@@ -5,12 +5,37 @@ import (
 	"net/http"
 )
 
+// Router dispatches requests to the handler registered for the method and
+// path. A request whose path is registered but not for its method must be
+// answered with 405 Method Not Allowed and an Allow header listing the
+// registered methods, not with 404.
 type Router interface {
-	// TODO
 	Get(string, http.Handler)
+	Post(string, http.Handler)
+	Put(string, http.Handler)
+	Patch(string, http.Handler)
+	Delete(string, http.Handler)
 	ServeHTTP(http.ResponseWriter, *http.Request)
 }
 
+// TrailingSlash is the policy for requests whose path differs from a
+// registered route only by a trailing slash, like /api/refresh-auth/ for
+// /api/refresh-auth.
+type TrailingSlash string
+
+const (
+	// TrailingSlashStrict serves only the exact registered path, the other
+	// form is not found.
+	TrailingSlashStrict TrailingSlash = "strict"
+
+	// TrailingSlashRedirect redirects to the registered path, 301 for GET
+	// and 307 for other methods so the body is resent.
+	TrailingSlashRedirect TrailingSlash = "redirect"
+
+	// TrailingSlashIgnore serves both forms with the registered route.
+	TrailingSlashIgnore TrailingSlash = "ignore"
+)
+
 // Param is a single URL parameter, consisting of a key and a value.
 type Param struct {
 	Key   string
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/caasmo/restinpieces/router"
 	"log"
 	"net/http"
@@ -16,6 +18,7 @@ const (
 	ReadHeaderTimeout = 2 * time.Second
 	WriteTimeout      = 3 * time.Second
 	IdleTimeout       = 1 * time.Minute
+	ShutdownTimeout   = 5 * time.Second
 )
 
 func New(addr string, r router.Router) *http.Server {
@@ -29,54 +32,138 @@ func New(addr string, r router.Router) *http.Server {
 	}
 }
 
-func Run(addr string, r router.Router) {
+// Exit codes of the server process. 2 is left out, it is the exit code of
+// an unrecovered panic.
+const (
+	ExitOK       = 0 // graceful shutdown after a stop signal
+	ExitInit     = 1 // app initialization failed: db, cache
+	ExitStart    = 3 // a start hook failed
+	ExitListen   = 4 // the http server failed, port in use?
+	ExitShutdown = 5 // graceful shutdown did not complete in time
+)
 
-	srv := &http.Server{
-		Addr:              addr,
-		Handler:           r,
-		ReadTimeout:       ReadTimeout,
-		ReadHeaderTimeout: ReadHeaderTimeout,
-		WriteTimeout:      WriteTimeout,
-		IdleTimeout:       IdleTimeout,
-	}
+// Errors returned by RunContext.
+var (
+	ErrStart    = errors.New("start")
+	ErrListen   = errors.New("listen")
+	ErrShutdown = errors.New("shutdown")
+)
 
-	// move most to server
-	go func() {
-		// always returns error. ErrServerClosed on graceful close
-		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-			if err == http.ErrServerClosed {
-				log.Print("ErrServerClosed, gratefull")
-				return
-			}
+// exitFunc ends the process in Run and shutdownTimeout bounds the shutdown,
+// both replaced in tests.
+var (
+	exitFunc        = os.Exit
+	shutdownTimeout = ShutdownTimeout
+)
 
-			// unexpected error. port in use?
-			log.Fatalf("ListenAndServe(): %v", err)
-		}
-	}()
+// Run runs onStart, serves r on addr until a stop signal, then shuts down
+// gracefully and exits the process with one of the Exit codes, logging the
+// reason. See RunContext for onStart and onShutdown.
+func Run(addr string, r router.Router, onStart func(context.Context) error, onShutdown func(context.Context)) {
+
+	ctx, cancel := context.WithCancel(context.Background())
 
-	ctx, stop := signal.NotifyContext(
-		context.Background(),
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig,
 		syscall.SIGHUP,  // kill -SIGHUP XXXX
 		syscall.SIGINT,  // kill -SIGINT XXXX or Ctrl+c
 		syscall.SIGQUIT, // kill -SIGQUIT XXXX
+		syscall.SIGTERM, // kill XXXX, docker stop, kubernetes pod termination
 	)
 
-	<-ctx.Done()
+	go func() {
+		select {
+		case s := <-sig:
+			// Reset signals default behavior, a second signal kills the process
+			signal.Stop(sig)
+			log.Printf("%v - shutting down...\n", s)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 
-	// Reset signals default behavior, similar to signal.Reset
-	stop()
-	log.Print("os.Interrupt - shutting down...\n")
+	err := RunContext(ctx, addr, r, onStart, onShutdown)
+	signal.Stop(sig)
+	cancel()
+
+	code := ExitOK
+	switch {
+	case err == nil:
+		log.Printf("gracefully stopped - exit %d\n", code)
+	case errors.Is(err, ErrStart):
+		code = ExitStart
+		log.Printf("%v - exit %d\n", err, code)
+	case errors.Is(err, ErrListen):
+		// unexpected error. port in use?
+		code = ExitListen
+		log.Printf("%v - exit %d\n", err, code)
+	default:
+		code = ExitShutdown
+		log.Printf("%v - exit %d\n", err, code)
+	}
 
-	gracefullCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	exitFunc(code)
+}
+
+// RunContext runs onStart, serves r on addr until ctx is done, then shuts
+// down gracefully. Unlike Run, it never exits the process.
+//
+// onStart, if not nil, runs before the server accepts requests, an error
+// aborts the run. onShutdown, if not nil, is called whatever ends the run,
+// once the server has stopped, with a context bounded by ShutdownTimeout.
+//
+// It returns nil after a graceful shutdown, or an error wrapping ErrStart,
+// ErrListen if the server could not serve or ErrShutdown if the shutdown
+// did not complete in time.
+func RunContext(ctx context.Context, addr string, r router.Router, onStart func(context.Context) error, onShutdown func(context.Context)) error {
+
+	if onStart != nil {
+		if err := onStart(ctx); err != nil {
+			runShutdown(onShutdown)
+			return fmt.Errorf("%w: %v", ErrStart, err)
+		}
+	}
+
+	srv := New(addr, r)
+
+	listenErr := make(chan error, 1)
+	go func() {
+		// always returns error. ErrServerClosed on graceful close
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			listenErr <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-listenErr:
+		runShutdown(onShutdown)
+		return fmt.Errorf("%w: %v", ErrListen, err)
+	}
+
+	gracefullCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancelShutdown()
 
-	if err := srv.Shutdown(gracefullCtx); err != nil {
-		log.Printf("shutdown error: %v\n", err)
-		defer os.Exit(1)
+	err := srv.Shutdown(gracefullCtx)
+	if onShutdown != nil {
+		onShutdown(gracefullCtx)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrShutdown, err)
+	}
+
+	return nil
+}
+
+// runShutdown calls onShutdown, if not nil, when the run ends before the
+// server served.
+func runShutdown(onShutdown func(context.Context)) {
+	if onShutdown == nil {
 		return
-	} else {
-		log.Printf("gracefully stopped\n")
 	}
 
-	defer os.Exit(0)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	onShutdown(ctx)
 }
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/caasmo/restinpieces/router/httprouter"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// runExitCode calls Run with exitFunc recording the exit code. Run has
+// registered its signal handling before calling onStart, so onStart can
+// signal the process safely.
+func runExitCode(t *testing.T, onStart func(context.Context) error, onShutdown func(context.Context)) int {
+	t.Helper()
+
+	defer func(f func(int)) { exitFunc = f }(exitFunc)
+	code := -1
+	exitFunc = func(c int) { code = c }
+
+	Run("127.0.0.1:0", httprouter.New(), onStart, onShutdown)
+
+	return code
+}
+
+func signalSelf(sig syscall.Signal) func(context.Context) error {
+	return func(context.Context) error {
+		return syscall.Kill(os.Getpid(), sig)
+	}
+}
+
+func TestRun_ExitCodeCleanShutdown(t *testing.T) {
+	shutdown := false
+	code := runExitCode(t, signalSelf(syscall.SIGINT), func(context.Context) { shutdown = true })
+
+	if code != ExitOK {
+		t.Errorf("exit code = %d, want %d", code, ExitOK)
+	}
+	if !shutdown {
+		t.Error("onShutdown not called")
+	}
+}
+
+func TestRun_ExitCodeStartFailure(t *testing.T) {
+	shutdown := false
+	start := func(context.Context) error { return errors.New("cache not warm") }
+	code := runExitCode(t, start, func(context.Context) { shutdown = true })
+
+	if code != ExitStart {
+		t.Errorf("exit code = %d, want %d", code, ExitStart)
+	}
+	if !shutdown {
+		t.Error("onShutdown not called, app resources would not be released")
+	}
+}
+
+func TestRun_SIGTERM(t *testing.T) {
+	shutdown := false
+	code := runExitCode(t, signalSelf(syscall.SIGTERM), func(context.Context) { shutdown = true })
+
+	if code != ExitOK {
+		t.Errorf("exit code = %d, want %d", code, ExitOK)
+	}
+	if !shutdown {
+		t.Error("onShutdown not called, SIGTERM did not take the graceful path")
+	}
+}
+
+func TestRunContext_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	shutdown := false
+	err := RunContext(ctx, "127.0.0.1:0", httprouter.New(), nil, func(context.Context) { shutdown = true })
+
+	if err != nil {
+		t.Fatalf("RunContext() = %v, want nil", err)
+	}
+	if !shutdown {
+		t.Error("onShutdown not called")
+	}
+}
+
+func TestRunContext_AddressInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	shutdown := false
+	err = RunContext(context.Background(), ln.Addr().String(), httprouter.New(), nil, func(context.Context) { shutdown = true })
+
+	if !errors.Is(err, ErrListen) {
+		t.Fatalf("RunContext() = %v, want wrapping ErrListen", err)
+	}
+	if !shutdown {
+		t.Error("onShutdown not called")
+	}
+}
+
+func TestRunContext_StartFailure(t *testing.T) {
+	errStart := errors.New("cache not warm")
+	err := RunContext(context.Background(), "127.0.0.1:0", httprouter.New(), func(context.Context) error { return errStart }, nil)
+
+	if !errors.Is(err, ErrStart) {
+		t.Fatalf("RunContext() = %v, want wrapping ErrStart", err)
+	}
+}
+
+func TestRun_ExitCodeShutdownTimeout(t *testing.T) {
+	defer func(d time.Duration) { shutdownTimeout = d }(shutdownTimeout)
+	shutdownTimeout = 50 * time.Millisecond
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	r := httprouter.New()
+	r.Get("/slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	}))
+
+	// signal once the handler is running, it outlives the shutdown timeout
+	start := func(context.Context) error {
+		go func() {
+			for {
+				resp, err := http.Get("http://" + addr + "/slow")
+				if err == nil {
+					resp.Body.Close()
+					return
+				}
+				select {
+				case <-entered:
+					return
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+		}()
+		go func() {
+			<-entered
+			syscall.Kill(os.Getpid(), syscall.SIGINT)
+		}()
+		return nil
+	}
+
+	var shutdownErr error
+	defer func(f func(int)) { exitFunc = f }(exitFunc)
+	code := -1
+	exitFunc = func(c int) { code = c }
+
+	Run(addr, r, start, func(ctx context.Context) { shutdownErr = ctx.Err() })
+
+	if code != ExitShutdown {
+		t.Errorf("exit code = %d, want %d", code, ExitShutdown)
+	}
+	if shutdownErr == nil {
+		t.Error("onShutdown context not done, want the expired shutdown context")
+	}
+}